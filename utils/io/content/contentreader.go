@@ -2,16 +2,15 @@ package content
 
 import (
 	"bufio"
+	"container/heap"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/jfrog/gofrog/http/retryexecutor"
 	"github.com/jfrog/jfrog-client-go/utils"
 	"github.com/jfrog/jfrog-client-go/utils/errorutils"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 	"io"
-	"os"
 	"reflect"
 	"sort"
 	"sync"
@@ -34,15 +33,67 @@ type ContentReader struct {
 	// Number of elements in the array (cache)
 	length int
 	empty  bool
+	// storage - backend used to open the source data files. Defaults to the local filesystem.
+	storage StorageDriver
+	// codec - spill codec expected to have been used to write the source files. Only needed so that
+	// sort/merge helpers can propagate it to the files they derive from this reader; the codec used to
+	// read any given file is auto-detected from that file's own header regardless.
+	codec SpillCodec
+	// decryptionKey - key to decrypt source files with, if they were written with WithEncryptionKey.
+	decryptionKey EncryptionKey
+	// concurrency - number of filesPaths read concurrently by run(). Defaults to 1 (fully sequential).
+	concurrency int
 }
 
-func NewContentReader(filePath string, arrayKey string) *ContentReader {
-	self := NewMultiSourceContentReader([]string{filePath}, arrayKey)
+// ContentReaderOption configures optional behavior of NewContentReader / NewMultiSourceContentReader.
+type ContentReaderOption func(*ContentReader)
+
+// WithReaderStorageDriver overrides the storage driver used to open the reader's source files.
+// Defaults to the local filesystem.
+func WithReaderStorageDriver(driver StorageDriver) ContentReaderOption {
+	return func(cr *ContentReader) {
+		cr.storage = driver
+	}
+}
+
+// WithReaderSpillCodec records which SpillCodec the reader's source files are expected to use. This
+// does not affect how a given file is read - that's auto-detected from its header - but lets sort/merge
+// helpers keep the files they derive from this reader on the same codec.
+func WithReaderSpillCodec(codec SpillCodec) ContentReaderOption {
+	return func(cr *ContentReader) {
+		cr.codec = codec
+	}
+}
+
+// WithReaderDecryptionKey supplies the key to decrypt source files written with WithEncryptionKey.
+func WithReaderDecryptionKey(key EncryptionKey) ContentReaderOption {
+	return func(cr *ContentReader) {
+		cr.decryptionKey = key
+	}
+}
+
+// WithConcurrency lets run() read up to n of the reader's source files in parallel, instead of the
+// default one-at-a-time. This only helps NewMultiSourceContentReader readers with more than one file
+// (e.g. fanning in per-repository AQL result pages); a single-file reader is unaffected. Record order
+// within a single file is always preserved, but the order in which records from different files are
+// interleaved into the reader's output becomes unspecified once n > 1.
+//
+// This only applies to the legacy NextRecord/run() path. ForEach (and Length, MergeReaders and the
+// sort helpers built on it) always reads the reader's files one at a time, regardless of concurrency,
+// since fn is not guaranteed safe to call from multiple goroutines at once.
+func WithConcurrency(n int) ContentReaderOption {
+	return func(cr *ContentReader) {
+		cr.concurrency = n
+	}
+}
+
+func NewContentReader(filePath string, arrayKey string, options ...ContentReaderOption) *ContentReader {
+	self := NewMultiSourceContentReader([]string{filePath}, arrayKey, options...)
 	self.empty = filePath == ""
 	return self
 }
 
-func NewMultiSourceContentReader(filePaths []string, arrayKey string) *ContentReader {
+func NewMultiSourceContentReader(filePaths []string, arrayKey string, options ...ContentReaderOption) *ContentReader {
 	self := ContentReader{}
 	self.filesPaths = filePaths
 	self.arrayKey = arrayKey
@@ -50,6 +101,11 @@ func NewMultiSourceContentReader(filePaths []string, arrayKey string) *ContentRe
 	self.errorsQueue = utils.NewErrorsQueue(utils.MaxBufferSize)
 	self.once = new(sync.Once)
 	self.empty = len(filePaths) == 0
+	self.storage = defaultStorageDriver
+	self.concurrency = 1
+	for _, option := range options {
+		option(&self)
+	}
 	return &self
 }
 
@@ -96,24 +152,111 @@ func (cr *ContentReader) Reset() {
 	cr.once = new(sync.Once)
 }
 
-func removeFileWithRetry(filePath string) error {
-	// Check if file exists before attempting to remove
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		log.Debug("File does not exist: %s", filePath)
+// ForEach opens and decodes the reader's files synchronously, in the caller's goroutine, invoking fn
+// once per element with a pointer to a fresh instance of prototype's type. Unlike NextRecord, which hands off to a
+// background goroutine and a channel that are only cleaned up once io.EOF is reached, ForEach reads and
+// closes each file deterministically and can be stopped early: iteration aborts as soon as fn returns a
+// non-nil error, or as soon as ctx is done. Unlike run(), ForEach always reads the reader's files one at
+// a time in the caller's goroutine; it does not honor WithConcurrency. Length and MergeReaders are built
+// on ForEach and inherit this; the k-way merge step behind SortContentReader/MergeSortedReaders is not -
+// it still pulls records through the legacy NextRecord path (see mergeSortedReadersByCalculatedKey) and
+// so is neither ctx-cancellable nor affected by WithConcurrency.
+func (cr *ContentReader) ForEach(ctx context.Context, prototype interface{}, fn func(record interface{}) error) error {
+	if cr.empty {
 		return nil
 	}
-	log.Debug("Attempting to remove file: %s", filePath)
-	executor := retryexecutor.RetryExecutor{
-		Context:                  context.Background(),
-		MaxRetries:               5,
-		RetriesIntervalMilliSecs: 100,
-		ErrorMessage:             "Failed to remove file",
-		LogMsgPrefix:             "Attempting removal",
-		ExecutionHandler: func() (bool, error) {
-			return false, errorutils.CheckError(os.Remove(filePath))
-		},
+	recordType := reflect.ValueOf(prototype).Type()
+	for _, filePath := range cr.filesPaths {
+		if err := cr.forEachInFile(ctx, filePath, recordType, fn); err != nil {
+			return err
+		}
 	}
-	return executor.Execute()
+	return nil
+}
+
+func (cr *ContentReader) forEachInFile(ctx context.Context, filePath string, recordType reflect.Type, fn func(record interface{}) error) error {
+	fd, err := cr.storage.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := fd.Close(); closeErr != nil {
+			log.Error(closeErr.Error())
+		}
+	}()
+
+	spill, err := newSpillReader(fd, cr.decryptionKey)
+	if err != nil {
+		return err
+	}
+	if closer, ok := spill.(io.Closer); ok {
+		defer func() {
+			if closeErr := closer.Close(); closeErr != nil {
+				log.Error(closeErr.Error())
+			}
+		}()
+	}
+
+	tracked := &errTrackingReader{r: spill}
+	dec := json.NewDecoder(bufio.NewReaderSize(tracked, 65536))
+	if err := findDecoderTargetPosition(dec, cr.arrayKey, true); err != nil {
+		if err == io.EOF {
+			if tracked.err != nil {
+				return tracked.err
+			}
+			return errorutils.CheckErrorf(cr.arrayKey + " not found")
+		}
+		return err
+	}
+	for dec.More() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		record := reflect.New(recordType).Interface()
+		if err := dec.Decode(record); err != nil {
+			return errorutils.CheckError(err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if tracked.err != nil {
+		return tracked.err
+	}
+	return nil
+}
+
+// errTrackingReader wraps a reader so a decode/decrypt failure further down the stream (e.g. a wrong
+// EncryptionKey rejecting an AES-GCM frame) isn't mistaken for a clean end of input: json.Decoder.More()
+// peeks the next byte and, on a read error, swallows it and simply reports false rather than propagating
+// it, so forEachInFile/readSingleFile check tracked.err once their dec.More() loop ends to tell a real
+// empty array from one that only looked empty because reading it failed.
+type errTrackingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (t *errTrackingReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && err != io.EOF {
+		t.err = err
+	}
+	return n, err
+}
+
+// ForEachTyped is a generic variant of ContentReader.ForEach that spares the caller from unwrapping the
+// reflected prototype itself: T is used as the prototype, and fn receives an already-typed record.
+func ForEachTyped[T any](ctx context.Context, cr *ContentReader, fn func(record T) error) error {
+	var prototype T
+	return cr.ForEach(ctx, prototype, func(record interface{}) error {
+		typedRecord, ok := record.(*T)
+		if !ok {
+			return errorutils.CheckErrorf("ForEachTyped: unexpected record type")
+		}
+		return fn(*typedRecord)
+	})
 }
 
 // Cleanup the reader data with retry
@@ -122,7 +265,7 @@ func (cr *ContentReader) Close() error {
 		if filePath == "" {
 			continue
 		}
-		if err := removeFileWithRetry(filePath); err != nil {
+		if err := cr.storage.Remove(filePath); err != nil {
 			return fmt.Errorf("failed to close reader: %w", err)
 		}
 	}
@@ -140,26 +283,50 @@ func (cr *ContentReader) Length() (int, error) {
 		return 0, nil
 	}
 	if cr.length == 0 {
-		for item := new(interface{}); cr.NextRecord(item) == nil; item = new(interface{}) {
-		}
-		cr.Reset()
-		if err := cr.GetError(); err != nil {
+		length := 0
+		err := cr.ForEach(context.Background(), new(interface{}), func(record interface{}) error {
+			length++
+			return nil
+		})
+		if err != nil {
 			return 0, err
 		}
+		cr.length = length
 	}
 	return cr.length, nil
 }
 
-// Open and read the files one by one. Push each array element into the channel.
-// The channel may block the thread, therefore should run async.
+// Open and read the files, push each array element into the channel, and return only once every file
+// has been fully read - the channel may block the thread, therefore should run async. Up to
+// cr.concurrency files are read in parallel; ordering across files is then unspecified, but each file's
+// own records are still pushed to the channel in order.
 func (cr *ContentReader) run() {
-	for _, filePath := range cr.filesPaths {
-		cr.readSingleFile(filePath)
+	if cr.concurrency <= 1 || len(cr.filesPaths) <= 1 {
+		for _, filePath := range cr.filesPaths {
+			cr.readSingleFile(filePath)
+		}
+		return
 	}
+
+	var wg sync.WaitGroup
+	// Bound the number of files open concurrently to cr.concurrency.
+	semaphore := make(chan struct{}, cr.concurrency)
+	for _, filePath := range cr.filesPaths {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(filePath string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			cr.readSingleFile(filePath)
+		}(filePath)
+	}
+	// The caller closes the data channel as soon as run() returns, so it must not return before every
+	// worker is done pushing its records.
+	wg.Wait()
 }
 
 func (cr *ContentReader) readSingleFile(filePath string) {
-	fd, err := os.Open(filePath)
+	fd, err := cr.storage.Open(filePath)
 	if err != nil {
 		log.Error(err.Error())
 		cr.errorsQueue.AddError(errorutils.CheckError(err))
@@ -172,11 +339,29 @@ func (cr *ContentReader) readSingleFile(filePath string) {
 			cr.errorsQueue.AddError(errorutils.CheckError(err))
 		}
 	}()
-	br := bufio.NewReaderSize(fd, 65536)
+	spill, err := newSpillReader(fd, cr.decryptionKey)
+	if err != nil {
+		cr.errorsQueue.AddError(err)
+		log.Error(err.Error())
+		return
+	}
+	if closer, ok := spill.(io.Closer); ok {
+		defer func() {
+			if closeErr := closer.Close(); closeErr != nil {
+				log.Error(closeErr.Error())
+			}
+		}()
+	}
+	tracked := &errTrackingReader{r: spill}
+	br := bufio.NewReaderSize(tracked, 65536)
 	dec := json.NewDecoder(br)
 	err = findDecoderTargetPosition(dec, cr.arrayKey, true)
 	if err != nil {
 		if err == io.EOF {
+			if tracked.err != nil {
+				cr.errorsQueue.AddError(errorutils.CheckError(tracked.err))
+				return
+			}
 			cr.errorsQueue.AddError(errorutils.CheckErrorf(cr.arrayKey + " not found"))
 			return
 		}
@@ -194,6 +379,9 @@ func (cr *ContentReader) readSingleFile(filePath string) {
 		}
 		cr.dataChannel <- ResultItem
 	}
+	if tracked.err != nil {
+		cr.errorsQueue.AddError(errorutils.CheckError(tracked.err))
+	}
 }
 
 func (cr *ContentReader) GetError() error {
@@ -221,7 +409,8 @@ func findDecoderTargetPosition(dec *json.Decoder, target string, isArray bool) e
 }
 
 func MergeReaders(arr []*ContentReader, arrayKey string) (contentReader *ContentReader, err error) {
-	cw, err := NewContentWriter(arrayKey, true, false)
+	source := firstReader(arr)
+	cw, err := NewContentWriter(arrayKey, true, false, inheritedWriterOptions(source)...)
 	if err != nil {
 		return nil, err
 	}
@@ -229,14 +418,17 @@ func MergeReaders(arr []*ContentReader, arrayKey string) (contentReader *Content
 		err = errors.Join(err, cw.Close())
 	}()
 	for _, cr := range arr {
-		for item := new(interface{}); cr.NextRecord(item) == nil; item = new(interface{}) {
-			cw.Write(*item)
-		}
-		if err = cr.GetError(); err != nil {
+		if err = cr.ForEach(context.Background(), new(interface{}), func(record interface{}) error {
+			// record is whatever ForEach's reflect.New(recordType) produced for the new(interface{})
+			// prototype, not a *interface{} itself; cw.Write re-marshals it as-is, the same way
+			// sortAndSaveBufferToFile writes out a SortRecord whose Record still holds such a pointer.
+			cw.Write(record)
+			return nil
+		}); err != nil {
 			return nil, err
 		}
 	}
-	contentReader = NewContentReader(cw.GetFilePath(), arrayKey)
+	contentReader = NewContentReader(cw.GetFilePath(), arrayKey, inheritedReaderOptions(source)...)
 	return contentReader, nil
 }
 
@@ -303,10 +495,10 @@ func splitReaderToSortedBufferSizeReadersByCalculatedKey(reader *ContentReader,
 	// Split and sort.
 	keysToContentItems := make(map[string]SortableContentItem)
 	allKeys := make([]string, 0, utils.MaxBufferSize)
-	for newRecord := new(interface{}); reader.NextRecord(newRecord) == nil; newRecord = new(interface{}) {
+	err := reader.ForEach(context.Background(), new(interface{}), func(newRecord interface{}) error {
 		sortKey, err := getKeyFunc(newRecord)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if _, exist := keysToContentItems[sortKey]; !exist {
@@ -314,22 +506,22 @@ func splitReaderToSortedBufferSizeReadersByCalculatedKey(reader *ContentReader,
 			keysToContentItems[sortKey] = recordWrapper
 			allKeys = append(allKeys, sortKey)
 			if len(allKeys) == utils.MaxBufferSize {
-				sortedFile, err := SortAndSaveBufferToFile(keysToContentItems, allKeys, ascendingOrder)
+				sortedFile, err := sortAndSaveBufferToFile(keysToContentItems, allKeys, ascendingOrder, reader)
 				if err != nil {
-					return nil, err
+					return err
 				}
 				splitReaders = append(splitReaders, sortedFile)
 				keysToContentItems = make(map[string]SortableContentItem)
 				allKeys = make([]string, 0, utils.MaxBufferSize)
 			}
 		}
-	}
-	if err := reader.GetError(); err != nil {
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	reader.Reset()
 	if len(allKeys) > 0 {
-		sortedFile, err := SortAndSaveBufferToFile(keysToContentItems, allKeys, ascendingOrder)
+		sortedFile, err := sortAndSaveBufferToFile(keysToContentItems, allKeys, ascendingOrder, reader)
 		if err != nil {
 			return nil, err
 		}
@@ -339,66 +531,165 @@ func splitReaderToSortedBufferSizeReadersByCalculatedKey(reader *ContentReader,
 	return splitReaders, nil
 }
 
+// inheritedWriterOptions builds the ContentWriter options needed to keep a spill file written from
+// source on the same storage backend and spill codec/encryption as source itself, so every intermediate
+// file produced by a sort or merge shares one encoding end-to-end.
+func inheritedWriterOptions(source *ContentReader) []ContentWriterOption {
+	if source == nil {
+		return nil
+	}
+	opts := []ContentWriterOption{WithWriterStorageDriver(source.storage)}
+	if source.codec != SpillCodecNone {
+		opts = append(opts, WithSpillCodec(source.codec))
+	}
+	if len(source.decryptionKey) > 0 {
+		opts = append(opts, WithEncryptionKey(source.decryptionKey))
+	}
+	return opts
+}
+
+// inheritedReaderOptions mirrors inheritedWriterOptions for the ContentReader wrapping a spill file that
+// was just written with inheritedWriterOptions(source).
+func inheritedReaderOptions(source *ContentReader) []ContentReaderOption {
+	if source == nil {
+		return nil
+	}
+	opts := []ContentReaderOption{WithReaderStorageDriver(source.storage)}
+	if source.codec != SpillCodecNone {
+		opts = append(opts, WithReaderSpillCodec(source.codec))
+	}
+	if len(source.decryptionKey) > 0 {
+		opts = append(opts, WithReaderDecryptionKey(source.decryptionKey))
+	}
+	return opts
+}
+
+// firstReader returns the first reader in readers, or nil if it's empty.
+func firstReader(readers []*ContentReader) *ContentReader {
+	if len(readers) == 0 {
+		return nil
+	}
+	return readers[0]
+}
+
+// heapItem is a single candidate record held by the k-way merge heap, tracking which run it came from
+// so that a replacement record can be pulled from the same run once it is popped.
+type heapItem struct {
+	key     string
+	run     int
+	payload interface{}
+}
+
+// mergeHeap is a container/heap.Interface over the current head record of every still-open run.
+// It pops records in ascending or descending key order, depending on ascendingOrder.
+type mergeHeap struct {
+	items          []*heapItem
+	ascendingOrder bool
+}
+
+func (h mergeHeap) Len() int { return len(h.items) }
+func (h mergeHeap) Less(i, j int) bool {
+	// Break ties on run so that, among duplicate keys, the item from the lowest run index is always
+	// popped first - otherwise container/heap leaves the winner among equal keys undefined.
+	if h.items[i].key == h.items[j].key {
+		return h.items[i].run < h.items[j].run
+	}
+	if h.ascendingOrder {
+		return h.items[i].key < h.items[j].key
+	}
+	return h.items[i].key > h.items[j].key
+}
+func (h mergeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *mergeHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(*heapItem))
+}
+
+func (h *mergeHeap) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	h.items = old[:n-1]
+	return item
+}
+
+// mergeFetchFunc returns the next keyed record for the given run. ok is false once the run is exhausted.
+type mergeFetchFunc func(run int) (key string, payload interface{}, ok bool)
+
+// kWayMergeSortedRuns merges numRuns sorted runs into a single sorted sequence using a min/max-heap,
+// achieving O(N*log(numRuns)) instead of the O(N*numRuns) cost of scanning every run on each step.
+// Records are pulled from fetch and handed to write in sorted order. In case of duplicate keys across
+// runs, only the first occurrence (the one from the lowest run index) is written and the rest are
+// silently dropped; this applies to both SortContentReaderByCalculatedKey and MergeSortedReaders.
+func kWayMergeSortedRuns(numRuns int, ascendingOrder bool, fetch mergeFetchFunc, write func(payload interface{})) {
+	h := &mergeHeap{ascendingOrder: ascendingOrder}
+	heap.Init(h)
+	push := func(run int) {
+		if key, payload, ok := fetch(run); ok {
+			heap.Push(h, &heapItem{key: key, run: run, payload: payload})
+		}
+	}
+	for i := 0; i < numRuns; i++ {
+		push(i)
+	}
+	for h.Len() > 0 {
+		top := heap.Pop(h).(*heapItem)
+		write(top.payload)
+		lastKey := top.key
+		push(top.run)
+		// Discard any further entries sharing the key we just wrote, refilling their runs as we go.
+		for h.Len() > 0 && h.items[0].key == lastKey {
+			dup := heap.Pop(h).(*heapItem)
+			push(dup.run)
+		}
+	}
+}
+
+// mergeSortedReadersByCalculatedKey does the k-way merge behind SortContentReaderByCalculatedKey. Unlike
+// the split phase it merges (splitReaderToSortedBufferSizeReadersByCalculatedKey, which reads through
+// ForEach), this still fetches records via NextRecord, one run at a time, the way every ForEach-based
+// caller used to: the heap merge in kWayMergeSortedRuns needs to pull a single record from whichever run
+// is on top, which doesn't fit ForEach's push-style "one callback per element in file order" contract. So
+// this step is neither ctx-cancellable nor sped up by WithConcurrency.
 func mergeSortedReadersByCalculatedKey(sortedReaders []*ContentReader, ascendingOrder bool) (contentReader *ContentReader, err error) {
 	if len(sortedReaders) == 0 {
 		contentReader = NewEmptyContentReader(DefaultKey)
 		return contentReader, nil
 	}
-	resultWriter, err := NewContentWriter(DefaultKey, true, false)
+	source := firstReader(sortedReaders)
+	resultWriter, err := NewContentWriter(DefaultKey, true, false, inheritedWriterOptions(source)...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		err = errors.Join(err, resultWriter.Close())
 	}()
-	currentContentItem := make([]*SortRecord, len(sortedReaders))
-	sortedFilesClone := make([]*ContentReader, len(sortedReaders))
-	copy(sortedFilesClone, sortedReaders)
-
-	for {
-		var candidateToWrite *SortRecord
-		smallestIndex := 0
-		for i := 0; i < len(sortedFilesClone); i++ {
-			if currentContentItem[i] == nil && sortedFilesClone[i] != nil {
-				record := new(SortRecord)
-				if err := sortedFilesClone[i].NextRecord(record); nil != err {
-					sortedFilesClone[i] = nil
-					continue
-				}
-				currentContentItem[i] = record
-			}
 
-			var candidateKey, currentKey string
-			if candidateToWrite != nil && currentContentItem[i] != nil {
-				candidateKey = candidateToWrite.Key
-				currentKey = currentContentItem[i].Key
-
-				// If there are two items with the same key - the second one will be removed
-				if candidateKey == currentKey {
-					currentContentItem[i] = nil
-				}
-			}
-			if candidateToWrite == nil || (currentContentItem[i] != nil && compareStrings(candidateKey, currentKey, ascendingOrder)) {
-				candidateToWrite = currentContentItem[i]
-				smallestIndex = i
-			}
+	fetch := func(run int) (string, interface{}, bool) {
+		record := new(SortRecord)
+		if err := sortedReaders[run].NextRecord(record); err != nil {
+			return "", nil, false
 		}
-		if candidateToWrite == nil {
-			break
-		}
-		resultWriter.Write(candidateToWrite.Record)
-		currentContentItem[smallestIndex] = nil
+		return record.Key, record, true
 	}
-	contentReader = NewContentReader(resultWriter.GetFilePath(), resultWriter.GetArrayKey())
+	kWayMergeSortedRuns(len(sortedReaders), ascendingOrder, fetch, func(payload interface{}) {
+		resultWriter.Write(payload.(*SortRecord).Record)
+	})
+
+	contentReader = NewContentReader(resultWriter.GetFilePath(), resultWriter.GetArrayKey(), inheritedReaderOptions(source)...)
 	return contentReader, nil
 }
 
 // Merge a slice of sorted content-readers into a single sorted content-reader.
+// The merge itself runs the same NextRecord-based k-way merge as mergeSortedReadersByCalculatedKey, so
+// it is not ctx-cancellable and WithConcurrency has no effect on it.
 func MergeSortedReaders(readerRecord SortableContentItem, sortedReaders []*ContentReader, ascendingOrder bool) (contentReader *ContentReader, err error) {
 	if len(sortedReaders) == 0 {
 		return NewEmptyContentReader(DefaultKey), nil
 	}
-	resultWriter, err := NewContentWriter(DefaultKey, true, false)
+	source := firstReader(sortedReaders)
+	resultWriter, err := NewContentWriter(DefaultKey, true, false, inheritedWriterOptions(source)...)
 	if err != nil {
 		return nil, err
 	}
@@ -410,41 +701,28 @@ func MergeSortedReaders(readerRecord SortableContentItem, sortedReaders []*Conte
 	value := reflect.ValueOf(readerRecord)
 	valueType := value.Type()
 
-	currentContentItem := make([]*SortableContentItem, len(sortedReaders))
-	sortedFilesClone := make([]*ContentReader, len(sortedReaders))
-	copy(sortedFilesClone, sortedReaders)
-
-	for {
-		var candidateToWrite *SortableContentItem
-		smallestIndex := 0
-		for i := 0; i < len(sortedFilesClone); i++ {
-			if currentContentItem[i] == nil && sortedFilesClone[i] != nil {
-				temp := (reflect.New(valueType)).Interface()
-				if err = sortedFilesClone[i].NextRecord(temp); nil != err {
-					sortedFilesClone[i] = nil
-					continue
-				}
-				// Expect to receive 'SortableContentItem'.
-				contentItem, ok := (temp).(SortableContentItem)
-				if !ok {
-					return nil, errorutils.CheckErrorf("attempting to sort a content-reader with unsortable items.")
-				}
-				currentContentItem[i] = &contentItem
-			}
-
-			if candidateToWrite == nil || (currentContentItem[i] != nil && compareStrings((*candidateToWrite).GetSortKey(),
-				(*currentContentItem[i]).GetSortKey(), ascendingOrder)) {
-				candidateToWrite = currentContentItem[i]
-				smallestIndex = i
-			}
+	var unsortableErr error
+	fetch := func(run int) (string, interface{}, bool) {
+		temp := (reflect.New(valueType)).Interface()
+		if err := sortedReaders[run].NextRecord(temp); err != nil {
+			return "", nil, false
 		}
-		if candidateToWrite == nil {
-			break
+		// Expect to receive 'SortableContentItem'.
+		contentItem, ok := (temp).(SortableContentItem)
+		if !ok {
+			unsortableErr = errorutils.CheckErrorf("attempting to sort a content-reader with unsortable items.")
+			return "", nil, false
 		}
-		resultWriter.Write(*candidateToWrite)
-		currentContentItem[smallestIndex] = nil
+		return contentItem.GetSortKey(), contentItem, true
+	}
+	kWayMergeSortedRuns(len(sortedReaders), ascendingOrder, fetch, func(payload interface{}) {
+		resultWriter.Write(payload.(SortableContentItem))
+	})
+	if unsortableErr != nil {
+		return nil, unsortableErr
 	}
-	contentReader = NewContentReader(resultWriter.GetFilePath(), resultWriter.GetArrayKey())
+
+	contentReader = NewContentReader(resultWriter.GetFilePath(), resultWriter.GetArrayKey(), inheritedReaderOptions(source)...)
 	return contentReader, nil
 }
 
@@ -456,10 +734,17 @@ func compareStrings(src, against string, ascendingOrder bool) bool {
 }
 
 func SortAndSaveBufferToFile(keysToContentItems map[string]SortableContentItem, allKeys []string, increasingOrder bool) (contentReader *ContentReader, err error) {
+	return sortAndSaveBufferToFile(keysToContentItems, allKeys, increasingOrder, nil)
+}
+
+// sortAndSaveBufferToFile is the internal implementation behind SortAndSaveBufferToFile, additionally
+// accepting the reader the buffer was split from, so the sorted run it writes out lands on the same
+// storage backend and spill codec/encryption as its source.
+func sortAndSaveBufferToFile(keysToContentItems map[string]SortableContentItem, allKeys []string, increasingOrder bool, source *ContentReader) (contentReader *ContentReader, err error) {
 	if len(allKeys) == 0 {
 		return nil, nil
 	}
-	writer, err := NewContentWriter(DefaultKey, true, false)
+	writer, err := NewContentWriter(DefaultKey, true, false, inheritedWriterOptions(source)...)
 	if err != nil {
 		return nil, err
 	}
@@ -474,7 +759,7 @@ func SortAndSaveBufferToFile(keysToContentItems map[string]SortableContentItem,
 	for _, v := range allKeys {
 		writer.Write(keysToContentItems[v])
 	}
-	contentReader = NewContentReader(writer.GetFilePath(), writer.GetArrayKey())
+	contentReader = NewContentReader(writer.GetFilePath(), writer.GetArrayKey(), inheritedReaderOptions(source)...)
 	return contentReader, nil
 }
 
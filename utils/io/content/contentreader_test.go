@@ -0,0 +1,144 @@
+package content
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRecord struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (r testRecord) GetSortKey() string {
+	return r.Key
+}
+
+// newTestReader writes items to a fresh in-memory spill file and returns a ContentReader over it,
+// sharing storage across every reader/writer in a test the same way inheritedReaderOptions does.
+func newTestReader(t *testing.T, storage StorageDriver, arrayKey string, items []SortableContentItem) *ContentReader {
+	cw, err := NewContentWriter(arrayKey, true, false, WithWriterStorageDriver(storage))
+	require.NoError(t, err)
+	for _, item := range items {
+		cw.Write(item)
+	}
+	require.NoError(t, cw.Close())
+	return NewContentReader(cw.GetFilePath(), arrayKey, WithReaderStorageDriver(storage))
+}
+
+func TestContentReaderWriterRoundTripWithMemoryStorage(t *testing.T) {
+	storage := NewMemoryStorageDriver()
+	want := []SortableContentItem{
+		testRecord{Key: "a", Value: "one"},
+		testRecord{Key: "b", Value: "two"},
+	}
+	reader := newTestReader(t, storage, DefaultKey, want)
+	defer func() { assert.NoError(t, reader.Close()) }()
+
+	var got []testRecord
+	err := ForEachTyped(context.Background(), reader, func(record testRecord) error {
+		got = append(got, record)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, []SortableContentItem{got[0], got[1]})
+}
+
+func TestContentReaderWriterRoundTripWithSpillCodecs(t *testing.T) {
+	codecNames := map[SpillCodec]string{SpillCodecNone: "none", SpillCodecGzip: "gzip", SpillCodecZstd: "zstd"}
+	for _, codec := range []SpillCodec{SpillCodecNone, SpillCodecGzip, SpillCodecZstd} {
+		t.Run(codecNames[codec], func(t *testing.T) {
+			storage := NewMemoryStorageDriver()
+			cw, err := NewContentWriter(DefaultKey, true, false, WithWriterStorageDriver(storage), WithSpillCodec(codec))
+			require.NoError(t, err)
+			cw.Write(testRecord{Key: "a", Value: "one"})
+			require.NoError(t, cw.Close())
+
+			// The reader is given no WithReaderSpillCodec hint, so it must auto-detect the codec from
+			// the spill file's header.
+			reader := NewContentReader(cw.GetFilePath(), DefaultKey, WithReaderStorageDriver(storage))
+			defer func() { assert.NoError(t, reader.Close()) }()
+
+			var got []testRecord
+			require.NoError(t, ForEachTyped(context.Background(), reader, func(record testRecord) error {
+				got = append(got, record)
+				return nil
+			}))
+			assert.Equal(t, []testRecord{{Key: "a", Value: "one"}}, got)
+		})
+	}
+}
+
+// TestMergeSortedReadersDedupesOnDuplicateKeys is a regression test for the mergeHeap tie-break: among
+// runs sharing a key, the item from the lowest run index (i.e. the earliest reader in sortedReaders)
+// must always be the one kept.
+func TestMergeSortedReadersDedupesOnDuplicateKeys(t *testing.T) {
+	storage := NewMemoryStorageDriver()
+	run0 := newTestReader(t, storage, DefaultKey, []SortableContentItem{
+		testRecord{Key: "a", Value: "run0"},
+		testRecord{Key: "b", Value: "run0"},
+	})
+	run1 := newTestReader(t, storage, DefaultKey, []SortableContentItem{
+		testRecord{Key: "b", Value: "run1"},
+		testRecord{Key: "c", Value: "run1"},
+	})
+
+	merged, err := MergeSortedReaders(testRecord{}, []*ContentReader{run0, run1}, true)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, merged.Close()) }()
+
+	var got []testRecord
+	require.NoError(t, ForEachTyped(context.Background(), merged, func(record testRecord) error {
+		got = append(got, record)
+		return nil
+	}))
+	assert.Equal(t, []testRecord{
+		{Key: "a", Value: "run0"},
+		{Key: "b", Value: "run0"},
+		{Key: "c", Value: "run1"},
+	}, got)
+}
+
+// TestForEachWithWrongDecryptionKeyFails is a regression test: reading an encrypted spill file back with
+// the wrong key must surface an error, not be mistaken for a clean, empty array.
+func TestForEachWithWrongDecryptionKeyFails(t *testing.T) {
+	storage := NewMemoryStorageDriver()
+	key := EncryptionKey("0123456789abcdef")
+	wrongKey := EncryptionKey("fedcba9876543210")
+
+	cw, err := NewContentWriter(DefaultKey, true, false, WithWriterStorageDriver(storage), WithEncryptionKey(key))
+	require.NoError(t, err)
+	cw.Write(testRecord{Key: "a", Value: "one"})
+	require.NoError(t, cw.Close())
+
+	reader := NewContentReader(cw.GetFilePath(), DefaultKey, WithReaderStorageDriver(storage), WithReaderDecryptionKey(wrongKey))
+	defer func() { assert.NoError(t, reader.Close()) }()
+
+	var got []testRecord
+	err = ForEachTyped(context.Background(), reader, func(record testRecord) error {
+		got = append(got, record)
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Empty(t, got)
+}
+
+func TestMergeReaders(t *testing.T) {
+	storage := NewMemoryStorageDriver()
+	first := newTestReader(t, storage, DefaultKey, []SortableContentItem{testRecord{Key: "a", Value: "one"}})
+	second := newTestReader(t, storage, DefaultKey, []SortableContentItem{testRecord{Key: "b", Value: "two"}})
+
+	merged, err := MergeReaders([]*ContentReader{first, second}, DefaultKey)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, merged.Close()) }()
+
+	var got []testRecord
+	require.NoError(t, ForEachTyped(context.Background(), merged, func(record testRecord) error {
+		got = append(got, record)
+		return nil
+	}))
+	assert.Equal(t, []testRecord{{Key: "a", Value: "one"}, {Key: "b", Value: "two"}}, got)
+}
@@ -0,0 +1,143 @@
+package content
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"io"
+)
+
+// DefaultKey is the array key used for intermediate content files produced internally by this package
+// (e.g. sort and merge results), where the caller has no specific key of its own to read by.
+const DefaultKey = "content"
+
+// SortableContentItem is implemented by any record type that can be written through a ContentWriter and
+// later merge-sorted by SortContentReader / MergeSortedReaders.
+type SortableContentItem interface {
+	GetSortKey() string
+}
+
+// ContentWriter incrementally writes JSON objects into the 'arrayKey' array of a spill file, to be later
+// consumed by a ContentReader. It backs the sort/merge helpers and any caller that needs to produce a
+// large content file without holding all of it in memory at once.
+type ContentWriter struct {
+	arrayKey        string
+	useArrayWrapper bool
+	appendSuffix    bool
+
+	storage       StorageDriver
+	codec         SpillCodec
+	encryptionKey EncryptionKey
+
+	filePath string
+	fd       FileWriter
+	spill    io.WriteCloser
+	bw       *bufio.Writer
+	enc      *json.Encoder
+
+	recordCount int
+}
+
+// ContentWriterOption configures optional behavior of NewContentWriter.
+type ContentWriterOption func(*ContentWriter)
+
+// WithWriterStorageDriver overrides the storage driver used to create the writer's spill file.
+// Defaults to the local filesystem.
+func WithWriterStorageDriver(driver StorageDriver) ContentWriterOption {
+	return func(cw *ContentWriter) {
+		cw.storage = driver
+	}
+}
+
+// WithSpillCodec wraps the writer's spill file with the given streaming compression codec. The codec is
+// recorded in the file's header so a ContentReader can auto-detect it. Defaults to SpillCodecNone.
+func WithSpillCodec(codec SpillCodec) ContentWriterOption {
+	return func(cw *ContentWriter) {
+		cw.codec = codec
+	}
+}
+
+// WithEncryptionKey wraps the writer's spill file with an AES-GCM encryption layer using the given
+// EncryptionKey. The same key must be supplied to the corresponding ContentReader via
+// WithReaderDecryptionKey.
+func WithEncryptionKey(key EncryptionKey) ContentWriterOption {
+	return func(cw *ContentWriter) {
+		cw.encryptionKey = key
+	}
+}
+
+func NewContentWriter(arrayKey string, useArrayWrapper, appendSuffix bool, options ...ContentWriterOption) (*ContentWriter, error) {
+	cw := &ContentWriter{
+		arrayKey:        arrayKey,
+		useArrayWrapper: useArrayWrapper,
+		appendSuffix:    appendSuffix,
+		storage:         defaultStorageDriver,
+	}
+	for _, option := range options {
+		option(cw)
+	}
+	if err := cw.openFile(); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *ContentWriter) openFile() error {
+	cw.filePath = cw.storage.TempName()
+	fd, err := cw.storage.Create(cw.filePath)
+	if err != nil {
+		return err
+	}
+	cw.fd = fd
+	spill, err := newSpillWriter(fd, cw.codec, cw.encryptionKey)
+	if err != nil {
+		return err
+	}
+	cw.spill = spill
+	cw.bw = bufio.NewWriterSize(spill, 65536)
+	cw.enc = json.NewEncoder(cw.bw)
+	if cw.useArrayWrapper {
+		if _, err := cw.bw.WriteString(`{"` + cw.arrayKey + `":[`); err != nil {
+			return errorutils.CheckError(err)
+		}
+	}
+	return nil
+}
+
+// Write appends data as the next element of the writer's array.
+func (cw *ContentWriter) Write(data interface{}) {
+	if cw.recordCount > 0 {
+		if _, err := cw.bw.WriteString(","); err != nil {
+			log.Error(err.Error())
+			return
+		}
+	}
+	if err := cw.enc.Encode(data); err != nil {
+		log.Error(err.Error())
+		return
+	}
+	cw.recordCount++
+}
+
+// Close terminates the array and flushes and closes the underlying spill file.
+func (cw *ContentWriter) Close() error {
+	if cw.useArrayWrapper {
+		if _, err := cw.bw.WriteString("]}"); err != nil {
+			return errorutils.CheckError(err)
+		}
+	}
+	if err := cw.bw.Flush(); err != nil {
+		return errorutils.CheckError(err)
+	}
+	return errors.Join(errorutils.CheckError(cw.spill.Close()), errorutils.CheckError(cw.fd.Close()))
+}
+
+func (cw *ContentWriter) GetFilePath() string {
+	return cw.filePath
+}
+
+func (cw *ContentWriter) GetArrayKey() string {
+	return cw.arrayKey
+}
@@ -0,0 +1,284 @@
+package content
+
+import (
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/klauspost/compress/zstd"
+	"io"
+)
+
+// SpillCodec selects the streaming compression wrapped around a ContentWriter/ContentReader spill file.
+type SpillCodec byte
+
+const (
+	// SpillCodecNone stores the spill file's JSON uncompressed, after the spill header.
+	SpillCodecNone SpillCodec = iota
+	// SpillCodecGzip compresses the spill file with gzip.
+	SpillCodecGzip
+	// SpillCodecZstd compresses the spill file with zstd, which is faster and compresses the kind of
+	// repetitive JSON records this package handles better than gzip.
+	SpillCodecZstd
+)
+
+// EncryptionKey is a caller-supplied AES-128/192/256 key (16, 24 or 32 bytes), used to wrap a spill file
+// with AES-GCM so that intermediate artifacts aren't left in plaintext on shared build agents.
+type EncryptionKey []byte
+
+// spillHeaderSize is the number of header bytes written at the start of every spill file: one byte
+// identifying the SpillCodec and one flag byte marking whether an encryption layer follows it. Readers
+// use this header to auto-detect how to read the file back, without the caller repeating the codec.
+const spillHeaderSize = 2
+
+const (
+	spillNotEncryptedFlag byte = 0
+	spillEncryptedFlag    byte = 1
+)
+
+// newSpillWriter wraps w with the spill header, an optional AES-GCM encryption layer, and the requested
+// compression codec, in that order (header, then encrypt, then compress - mirrored in reverse by
+// newSpillReader).
+func newSpillWriter(w io.Writer, codec SpillCodec, key EncryptionKey) (io.WriteCloser, error) {
+	flag := spillNotEncryptedFlag
+	if len(key) > 0 {
+		flag = spillEncryptedFlag
+	}
+	if _, err := w.Write([]byte{byte(codec), flag}); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+
+	var stream io.Writer = w
+	var encLayer io.Closer
+	if len(key) > 0 {
+		gw, err := newGCMWriter(w, key)
+		if err != nil {
+			return nil, err
+		}
+		stream, encLayer = gw, gw
+	}
+
+	switch codec {
+	case SpillCodecNone:
+		return &layeredWriteCloser{Writer: stream, closers: []io.Closer{encLayer}}, nil
+	case SpillCodecGzip:
+		gz := gzip.NewWriter(stream)
+		return &layeredWriteCloser{Writer: gz, closers: []io.Closer{gz, encLayer}}, nil
+	case SpillCodecZstd:
+		zw, err := zstd.NewWriter(stream)
+		if err != nil {
+			return nil, errorutils.CheckError(err)
+		}
+		return &layeredWriteCloser{Writer: zw, closers: []io.Closer{zw, encLayer}}, nil
+	default:
+		return nil, errorutils.CheckErrorf("content: unknown spill codec %d", codec)
+	}
+}
+
+// newSpillReader reads the spill header written by newSpillWriter off r and returns a reader that
+// transparently decrypts and decompresses the rest of the stream. If the returned reader also implements
+// io.Closer, the caller should close it once done, to release codec resources (e.g. the zstd decoder).
+func newSpillReader(r io.Reader, key EncryptionKey) (io.Reader, error) {
+	header := make([]byte, spillHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	codec := SpillCodec(header[0])
+	encrypted := header[1] == spillEncryptedFlag
+
+	var stream io.Reader = r
+	if encrypted {
+		if len(key) == 0 {
+			return nil, errorutils.CheckErrorf("content: spill file is encrypted but no decryption key was provided")
+		}
+		gr, err := newGCMReader(r, key)
+		if err != nil {
+			return nil, err
+		}
+		stream = gr
+	}
+
+	switch codec {
+	case SpillCodecNone:
+		// Wrap stream so its concrete type (e.g. the caller's *os.File) can't leak through an io.Closer
+		// assertion: the caller already owns and closes r itself, and a passthrough here would make
+		// forEachInFile/readSingleFile close the same file twice.
+		return struct{ io.Reader }{stream}, nil
+	case SpillCodecGzip:
+		return gzip.NewReader(stream)
+	case SpillCodecZstd:
+		zr, err := zstd.NewReader(stream)
+		if err != nil {
+			return nil, errorutils.CheckError(err)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, errorutils.CheckErrorf("content: unknown spill codec %d", codec)
+	}
+}
+
+// layeredWriteCloser writes through to an inner compressor/encryptor and closes an ordered chain of
+// layers (e.g. compressor then encryptor) when done, joining any errors encountered.
+type layeredWriteCloser struct {
+	io.Writer
+	closers []io.Closer
+}
+
+func (l *layeredWriteCloser) Close() error {
+	var err error
+	for _, c := range l.closers {
+		if c == nil {
+			continue
+		}
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// gcmChunkSize is the plaintext size of each AES-GCM frame. AES-GCM authenticates one buffer at a time,
+// so a long stream is split into fixed-size frames, each sealed independently and length-prefixed.
+const gcmChunkSize = 64 * 1024
+
+type gcmWriter struct {
+	w         io.Writer
+	gcm       cipher.AEAD
+	baseNonce []byte
+	counter   uint64
+	buf       []byte
+}
+
+func newGCMWriter(w io.Writer, key EncryptionKey) (*gcmWriter, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	if _, err := w.Write(nonce); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	return &gcmWriter{w: w, gcm: gcm, baseNonce: nonce, buf: make([]byte, 0, gcmChunkSize)}, nil
+}
+
+func (g *gcmWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		n := gcmChunkSize - len(g.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		g.buf = append(g.buf, p[:n]...)
+		p = p[n:]
+		if len(g.buf) == gcmChunkSize {
+			if err := g.flushChunk(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (g *gcmWriter) flushChunk() error {
+	if len(g.buf) == 0 {
+		return nil
+	}
+	sealed := g.gcm.Seal(nil, g.frameNonce(), g.buf, nil)
+	if err := binary.Write(g.w, binary.BigEndian, uint32(len(sealed))); err != nil {
+		return errorutils.CheckError(err)
+	}
+	if _, err := g.w.Write(sealed); err != nil {
+		return errorutils.CheckError(err)
+	}
+	g.counter++
+	g.buf = g.buf[:0]
+	return nil
+}
+
+func (g *gcmWriter) frameNonce() []byte {
+	nonce := make([]byte, len(g.baseNonce))
+	copy(nonce, g.baseNonce)
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], g.counter)
+	return nonce
+}
+
+func (g *gcmWriter) Close() error {
+	return g.flushChunk()
+}
+
+type gcmReader struct {
+	r         io.Reader
+	gcm       cipher.AEAD
+	baseNonce []byte
+	counter   uint64
+	buf       []byte
+	pos       int
+}
+
+func newGCMReader(r io.Reader, key EncryptionKey) (*gcmReader, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	return &gcmReader{r: r, gcm: gcm, baseNonce: nonce}, nil
+}
+
+func (g *gcmReader) Read(p []byte) (int, error) {
+	if g.pos >= len(g.buf) {
+		if err := g.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, g.buf[g.pos:])
+	g.pos += n
+	return n, nil
+}
+
+func (g *gcmReader) readChunk() error {
+	var length uint32
+	if err := binary.Read(g.r, binary.BigEndian, &length); err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return errorutils.CheckError(err)
+	}
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(g.r, sealed); err != nil {
+		return errorutils.CheckError(err)
+	}
+	plain, err := g.gcm.Open(nil, g.frameNonce(), sealed, nil)
+	if err != nil {
+		return errorutils.CheckError(err)
+	}
+	g.counter++
+	g.buf = plain
+	g.pos = 0
+	return nil
+}
+
+func (g *gcmReader) frameNonce() []byte {
+	nonce := make([]byte, len(g.baseNonce))
+	copy(nonce, g.baseNonce)
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], g.counter)
+	return nonce
+}
+
+func newGCM(key EncryptionKey) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errorutils.CheckError(err)
+	}
+	return gcm, nil
+}
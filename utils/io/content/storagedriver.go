@@ -0,0 +1,133 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/jfrog/gofrog/http/retryexecutor"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FileWriter is the subset of *os.File that a StorageDriver.Create result must support.
+type FileWriter interface {
+	io.WriteCloser
+}
+
+// StorageDriver abstracts where ContentReader/ContentWriter spill files are stored, so that sort/merge
+// intermediate data can be redirected away from the local filesystem (e.g. to tmpfs, object storage, or
+// an in-memory buffer in tests) without touching the sort/merge algorithms themselves.
+type StorageDriver interface {
+	// Create opens name for writing, creating or truncating it as needed.
+	Create(name string) (FileWriter, error)
+	// Open opens name for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Remove deletes name. It is not an error for name to not exist.
+	Remove(name string) error
+	// TempName returns a new, unique name suitable for a spill file.
+	TempName() string
+}
+
+// defaultStorageDriver is used by ContentReader/ContentWriter when no WithReaderStorageDriver /
+// WithWriterStorageDriver option is supplied, preserving the historical local-filesystem behavior.
+var defaultStorageDriver StorageDriver = localFSDriver{}
+
+// localFSDriver is the default StorageDriver. It stores spill files as regular files under the OS temp
+// directory, the same way ContentReader/ContentWriter have always behaved.
+type localFSDriver struct{}
+
+func (localFSDriver) Create(name string) (FileWriter, error) {
+	fd, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	return fd, errorutils.CheckError(err)
+}
+
+func (localFSDriver) Open(name string) (io.ReadCloser, error) {
+	fd, err := os.Open(name)
+	return fd, errorutils.CheckError(err)
+}
+
+func (localFSDriver) Remove(filePath string) error {
+	// Check if file exists before attempting to remove
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		log.Debug("File does not exist: %s", filePath)
+		return nil
+	}
+	log.Debug("Attempting to remove file: %s", filePath)
+	executor := retryexecutor.RetryExecutor{
+		Context:                  context.Background(),
+		MaxRetries:               5,
+		RetriesIntervalMilliSecs: 100,
+		ErrorMessage:             "Failed to remove file",
+		LogMsgPrefix:             "Attempting removal",
+		ExecutionHandler: func() (bool, error) {
+			return false, errorutils.CheckError(os.Remove(filePath))
+		},
+	}
+	return executor.Execute()
+}
+
+func (localFSDriver) TempName() string {
+	fd, err := os.CreateTemp("", "content")
+	if err != nil {
+		// Extremely unlikely to be hit; Create will surface the real error when it opens this name.
+		return fmt.Sprintf("%s%ccontent-%d", os.TempDir(), os.PathSeparator, time.Now().UnixNano())
+	}
+	name := fd.Name()
+	_ = fd.Close()
+	return name
+}
+
+// NewMemoryStorageDriver returns a StorageDriver that keeps every spill file entirely in memory. It is
+// intended for tests and small-scale callers that want to avoid touching disk, and is not suitable for
+// content too large to comfortably fit in memory.
+func NewMemoryStorageDriver() StorageDriver {
+	return &memoryDriver{files: make(map[string]*bytes.Buffer)}
+}
+
+type memoryDriver struct {
+	mu    sync.Mutex
+	files map[string]*bytes.Buffer
+	seq   int64
+}
+
+func (d *memoryDriver) Create(name string) (FileWriter, error) {
+	buf := new(bytes.Buffer)
+	d.mu.Lock()
+	d.files[name] = buf
+	d.mu.Unlock()
+	return nopCloseWriter{buf}, nil
+}
+
+func (d *memoryDriver) Open(name string) (io.ReadCloser, error) {
+	d.mu.Lock()
+	buf, ok := d.files[name]
+	d.mu.Unlock()
+	if !ok {
+		return nil, errorutils.CheckErrorf("memory storage driver: file %q not found", name)
+	}
+	return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+}
+
+func (d *memoryDriver) Remove(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.files, name)
+	return nil
+}
+
+func (d *memoryDriver) TempName() string {
+	id := atomic.AddInt64(&d.seq, 1)
+	return fmt.Sprintf("mem-content-%d", id)
+}
+
+// nopCloseWriter adapts a *bytes.Buffer to FileWriter for memoryDriver.
+type nopCloseWriter struct {
+	*bytes.Buffer
+}
+
+func (nopCloseWriter) Close() error { return nil }